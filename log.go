@@ -0,0 +1,93 @@
+package scope
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+
+	"github.com/snowmerak/go-scope/log"
+)
+
+// defaultLoggerBox lets defaultLoggerValue hold a log.Logger behind
+// atomic.Value, which requires every Store to use the same concrete type.
+type defaultLoggerBox struct {
+	logger log.Logger
+}
+
+var defaultLoggerValue atomic.Value
+
+func init() {
+	defaultLoggerValue.Store(defaultLoggerBox{logger: log.Discard})
+}
+
+// SetDefaultLogger changes the Logger Catch, With, and Wrap fall back to
+// when ctx carries none of its own (see the log subpackage). Passing nil
+// restores the built-in discard logger.
+func SetDefaultLogger(logger log.Logger) {
+	if logger == nil {
+		logger = log.Discard
+	}
+	defaultLoggerValue.Store(defaultLoggerBox{logger: logger})
+}
+
+func defaultLogger() log.Logger {
+	return defaultLoggerValue.Load().(defaultLoggerBox).logger
+}
+
+// effectiveLogger resolves the Logger a scoped operation should use: the
+// one attached to ctx via log.WithLogger if any (with any log.With fields
+// layered on), otherwise the package-level default set by
+// SetDefaultLogger.
+func effectiveLogger(ctx context.Context) log.Logger {
+	return log.Resolve(ctx, defaultLogger())
+}
+
+// logPanic reports a recovered panic through whichever Logger ctx (or the
+// package default) resolves to. When that Logger resolves to the built-in
+// discard, even through fields layered on via log.With, it returns before
+// touching reflect or building the stack trace string. With no log.With
+// fields on ctx that path allocates nothing; when ctx carries such fields,
+// resolving the logger allocates the field-carrying wrapper before the
+// log.IsDiscard check.
+func logPanic(ctx context.Context, input any, se *StackError) {
+	logger := effectiveLogger(ctx)
+	if log.IsDiscard(logger) {
+		return
+	}
+	logger.Error("scope: panic recovered",
+		"event", "panic",
+		"input_type", reflect.TypeOf(input),
+		"stack", se.StackTrace(),
+	)
+}
+
+// logCloseError reports a closer registered with With that failed or
+// panicked while cleaning up. site is the file:line the closer was
+// registered from.
+func logCloseError(ctx context.Context, input any, site string, err error) {
+	logger := effectiveLogger(ctx)
+	if log.IsDiscard(logger) {
+		return
+	}
+	logger.Error("scope: cleanup closer failed",
+		"event", "close_error",
+		"input_type", reflect.TypeOf(input),
+		"site", site,
+		"error", err,
+	)
+}
+
+// logRollback reports Wrap's catcher running because the wrapped body
+// returned a non-nil error (as opposed to panicking, which goes through
+// logPanic instead).
+func logRollback(ctx context.Context, input any, err error) {
+	logger := effectiveLogger(ctx)
+	if log.IsDiscard(logger) {
+		return
+	}
+	logger.Error("scope: wrap rolled back",
+		"event", "rollback",
+		"input_type", reflect.TypeOf(input),
+		"error", err,
+	)
+}
@@ -0,0 +1,140 @@
+// Package log provides the minimal context-scoped structured logger that
+// scope threads through Catch, With, and Wrap. It deliberately has no
+// dependency on the parent scope package, or on any particular logging
+// library: callers adapt their logger of choice to the Logger interface.
+package log
+
+import "context"
+
+// Logger is the structured logging interface scope looks for in context.
+// Each method takes alternating key/value pairs, matching the convention
+// used by log/slog without requiring a dependency on it.
+type Logger interface {
+	Debug(msg string, kv ...any)
+	Info(msg string, kv ...any)
+	Warn(msg string, kv ...any)
+	Error(msg string, kv ...any)
+}
+
+type discard struct{}
+
+func (discard) Debug(string, ...any) {}
+func (discard) Info(string, ...any)  {}
+func (discard) Warn(string, ...any)  {}
+func (discard) Error(string, ...any) {}
+
+// Discard is the no-op Logger used whenever neither a context logger nor a
+// package-level default has been configured. It is comparable with == so
+// callers can skip building log fields entirely on the common, logger-free
+// path.
+var Discard Logger = discard{}
+
+type contextKey struct{}
+
+// entry is what's actually stored in context: an explicitly attached
+// logger (nil if none has been set yet) plus the fields accumulated by
+// With calls layered on top of it.
+type entry struct {
+	logger Logger
+	fields []any
+}
+
+func entryFrom(ctx context.Context) entry {
+	if e, ok := ctx.Value(contextKey{}).(entry); ok {
+		return e
+	}
+	return entry{}
+}
+
+// WithLogger attaches logger to ctx, replacing any logger attached by an
+// earlier WithLogger call and clearing fields accumulated by With.
+func WithLogger(ctx context.Context, logger Logger) context.Context {
+	if logger == nil {
+		logger = Discard
+	}
+	return context.WithValue(ctx, contextKey{}, entry{logger: logger})
+}
+
+// With layers kv onto whatever fields ctx already carries, returning a
+// context whose From (or Resolve) prepends kv to the fields of every
+// subsequent call. This is the logctx pattern: accumulate request-scoped
+// fields as a context is threaded deeper without re-stating them at every
+// call site. Unlike WithLogger, With does not itself attach a logger, so
+// calling it on a context with none attached still lets a caller's
+// fallback (see Resolve) take over later.
+func With(ctx context.Context, kv ...any) context.Context {
+	if len(kv) == 0 {
+		return ctx
+	}
+	prev := entryFrom(ctx)
+	fields := make([]any, 0, len(prev.fields)+len(kv))
+	fields = append(fields, prev.fields...)
+	fields = append(fields, kv...)
+	return context.WithValue(ctx, contextKey{}, entry{logger: prev.logger, fields: fields})
+}
+
+// From returns the Logger attached to ctx (with any With fields layered on
+// top), or Discard if no logger was ever attached via WithLogger. In that
+// second case, With fields on ctx still wrap Discard, so the result is not
+// == Discard; use IsDiscard to detect it.
+func From(ctx context.Context) Logger {
+	return Resolve(ctx, nil)
+}
+
+// Resolve is like From, but falls back to fallback instead of Discard when
+// ctx carries no logger explicitly attached via WithLogger. fallback is
+// still wrapped with any fields accumulated via With, so a package-level
+// default logger (see scope.SetDefaultLogger) keeps seeing request-scoped
+// fields even on a context that only ever called With, never WithLogger. A
+// nil fallback behaves like Discard.
+func Resolve(ctx context.Context, fallback Logger) Logger {
+	e := entryFrom(ctx)
+	logger := e.logger
+	if logger == nil {
+		if fallback == nil {
+			logger = Discard
+		} else {
+			logger = fallback
+		}
+	}
+	if len(e.fields) == 0 {
+		return logger
+	}
+	return &fieldLogger{base: logger, fields: e.fields}
+}
+
+// IsDiscard reports whether l is Discard itself, or a Logger built by
+// Resolve/With that only ever forwards to Discard (a *fieldLogger whose
+// base, however many layers deep, bottoms out at Discard). Resolve must
+// allocate a *fieldLogger wrapper whenever fields were accumulated via
+// With, even with no logger ever attached, so comparing l == Discard
+// alone no longer detects the no-op case; callers that want to skip
+// building log fields on the logger-free path should check this instead.
+func IsDiscard(l Logger) bool {
+	for {
+		fl, ok := l.(*fieldLogger)
+		if !ok {
+			return l == Discard
+		}
+		l = fl.base
+	}
+}
+
+// fieldLogger prepends a fixed set of fields, captured by With, to every
+// call forwarded to base.
+type fieldLogger struct {
+	base   Logger
+	fields []any
+}
+
+func (f *fieldLogger) merge(kv []any) []any {
+	all := make([]any, 0, len(f.fields)+len(kv))
+	all = append(all, f.fields...)
+	all = append(all, kv...)
+	return all
+}
+
+func (f *fieldLogger) Debug(msg string, kv ...any) { f.base.Debug(msg, f.merge(kv)...) }
+func (f *fieldLogger) Info(msg string, kv ...any)  { f.base.Info(msg, f.merge(kv)...) }
+func (f *fieldLogger) Warn(msg string, kv ...any)  { f.base.Warn(msg, f.merge(kv)...) }
+func (f *fieldLogger) Error(msg string, kv ...any) { f.base.Error(msg, f.merge(kv)...) }
@@ -0,0 +1,110 @@
+package log
+
+import (
+	"context"
+	"testing"
+)
+
+type recordingLogger struct {
+	msg string
+	kv  []any
+}
+
+func (r *recordingLogger) Debug(msg string, kv ...any) { r.msg, r.kv = msg, kv }
+func (r *recordingLogger) Info(msg string, kv ...any)  { r.msg, r.kv = msg, kv }
+func (r *recordingLogger) Warn(msg string, kv ...any)  { r.msg, r.kv = msg, kv }
+func (r *recordingLogger) Error(msg string, kv ...any) { r.msg, r.kv = msg, kv }
+
+func TestFromDefaultsToDiscard(t *testing.T) {
+	if From(context.Background()) != Discard {
+		t.Error("expected From to return Discard for a context with no logger attached")
+	}
+}
+
+func TestWithLoggerRoundTrips(t *testing.T) {
+	rec := &recordingLogger{}
+	ctx := WithLogger(context.Background(), rec)
+	if From(ctx) != Logger(rec) {
+		t.Error("expected From to return the attached logger")
+	}
+}
+
+func TestWithPrependsFields(t *testing.T) {
+	rec := &recordingLogger{}
+	ctx := WithLogger(context.Background(), rec)
+	ctx = With(ctx, "request_id", "abc")
+
+	From(ctx).Info("hello", "extra", 1)
+
+	want := []any{"request_id", "abc", "extra", 1}
+	if len(rec.kv) != len(want) {
+		t.Fatalf("expected fields %v, got %v", want, rec.kv)
+	}
+	for i := range want {
+		if rec.kv[i] != want[i] {
+			t.Fatalf("expected fields %v, got %v", want, rec.kv)
+		}
+	}
+}
+
+func TestWithNoFieldsIsNoop(t *testing.T) {
+	ctx := context.Background()
+	if With(ctx) != ctx {
+		t.Error("expected With with no kv pairs to return ctx unchanged")
+	}
+}
+
+func TestResolveFallsBackWhenNoLoggerAttached(t *testing.T) {
+	fallback := &recordingLogger{}
+	ctx := context.Background()
+	if Resolve(ctx, fallback) != Logger(fallback) {
+		t.Error("expected Resolve to use the fallback when ctx has no attached logger")
+	}
+}
+
+func TestResolveUsesFallbackWithFieldsFromWithAlone(t *testing.T) {
+	fallback := &recordingLogger{}
+	ctx := With(context.Background(), "request_id", "abc")
+
+	Resolve(ctx, fallback).Info("hello")
+
+	want := []any{"request_id", "abc"}
+	if len(fallback.kv) != len(want) || fallback.kv[0] != want[0] || fallback.kv[1] != want[1] {
+		t.Errorf("expected fallback to receive fields accumulated via With, got %v", fallback.kv)
+	}
+}
+
+func TestIsDiscardTrueForDiscardItself(t *testing.T) {
+	if !IsDiscard(Discard) {
+		t.Error("expected IsDiscard(Discard) to be true")
+	}
+}
+
+func TestIsDiscardTrueThroughFieldsOverDiscard(t *testing.T) {
+	ctx := With(context.Background(), "request_id", "abc")
+	if !IsDiscard(Resolve(ctx, nil)) {
+		t.Error("expected IsDiscard to see through a fieldLogger wrapping Discard")
+	}
+}
+
+func TestIsDiscardFalseForExplicitLogger(t *testing.T) {
+	rec := &recordingLogger{}
+	if IsDiscard(rec) {
+		t.Error("expected IsDiscard(rec) to be false for a real logger")
+	}
+
+	ctx := WithLogger(context.Background(), rec)
+	ctx = With(ctx, "request_id", "abc")
+	if IsDiscard(Resolve(ctx, nil)) {
+		t.Error("expected IsDiscard to be false when fields wrap a real logger")
+	}
+}
+
+func TestResolvePrefersExplicitLoggerOverFallback(t *testing.T) {
+	explicit := &recordingLogger{}
+	fallback := &recordingLogger{}
+	ctx := WithLogger(context.Background(), explicit)
+	if Resolve(ctx, fallback) != Logger(explicit) {
+		t.Error("expected Resolve to prefer the explicitly attached logger over the fallback")
+	}
+}
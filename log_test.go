@@ -0,0 +1,117 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	scopelog "github.com/snowmerak/go-scope/log"
+)
+
+type recordingLogger struct {
+	events []string
+}
+
+func (r *recordingLogger) Debug(string, ...any) {}
+func (r *recordingLogger) Info(string, ...any)  {}
+func (r *recordingLogger) Warn(string, ...any)  {}
+func (r *recordingLogger) Error(msg string, kv ...any) {
+	for i := 0; i+1 < len(kv); i += 2 {
+		if kv[i] == "event" {
+			r.events = append(r.events, kv[i+1].(string))
+		}
+	}
+}
+
+func TestCatchLogsPanicWhenLoggerAttached(t *testing.T) {
+	rec := &recordingLogger{}
+	ctx := scopelog.WithLogger(context.Background(), rec)
+
+	fn := func(ctx context.Context, i int) (int, error) {
+		panic(errors.New("boom"))
+	}
+	caught := Catch(fn)
+	_, _ = caught(ctx, 1)
+
+	if len(rec.events) != 1 || rec.events[0] != "panic" {
+		t.Errorf("expected a single panic event, got %v", rec.events)
+	}
+}
+
+func TestCatchIsSilentWithoutLogger(t *testing.T) {
+	fn := func(ctx context.Context, i int) (int, error) {
+		panic(errors.New("boom"))
+	}
+	caught := Catch(fn)
+	_, _ = caught(context.Background(), 1)
+	// No logger attached and no default set: nothing to assert on beyond
+	// "this didn't panic the test", since logPanic is a no-op here.
+}
+
+func TestWithLogsCloseError(t *testing.T) {
+	rec := &recordingLogger{}
+	ctx := scopelog.WithLogger(context.Background(), rec)
+
+	fn := func(ctx context.Context, capture func(io.Closer), captureFunc func(func() error), captureCtx func(func(context.Context) error), i int) (int, error) {
+		captureFunc(func() error { return errors.New("close failed") })
+		return i, nil
+	}
+	w := With(fn)
+	_, _ = w(ctx, 1)
+
+	if len(rec.events) != 1 || rec.events[0] != "close_error" {
+		t.Errorf("expected a single close_error event, got %v", rec.events)
+	}
+}
+
+func TestWrapLogsRollback(t *testing.T) {
+	rec := &recordingLogger{}
+	ctx := scopelog.WithLogger(context.Background(), rec)
+
+	wrapped := Wrap(
+		func(ctx context.Context, check func(error) bool, i int, s *struct{}) (int, error) {
+			return 0, errors.New("body failed")
+		},
+		func(s *struct{}, err error) {},
+	)
+	_, _ = wrapped(ctx, 1, &struct{}{})
+
+	if len(rec.events) != 1 || rec.events[0] != "rollback" {
+		t.Errorf("expected a single rollback event, got %v", rec.events)
+	}
+}
+
+func TestSetDefaultLoggerAppliesAfterLogWithFieldsAlone(t *testing.T) {
+	rec := &recordingLogger{}
+	SetDefaultLogger(rec)
+	defer SetDefaultLogger(nil)
+
+	ctx := scopelog.With(context.Background(), "request_id", "abc")
+
+	fn := func(ctx context.Context, i int) (int, error) {
+		panic(errors.New("boom"))
+	}
+	caught := Catch(fn)
+	_, _ = caught(ctx, 1)
+
+	if len(rec.events) != 1 || rec.events[0] != "panic" {
+		t.Errorf("expected the default logger to still capture the panic event after log.With alone, got %v", rec.events)
+	}
+}
+
+func TestSetDefaultLoggerAppliesWithoutContextLogger(t *testing.T) {
+	rec := &recordingLogger{}
+	SetDefaultLogger(rec)
+	defer SetDefaultLogger(nil)
+
+	fn := func(ctx context.Context, i int) (int, error) {
+		panic(errors.New("boom"))
+	}
+	caught := Catch(fn)
+	_, _ = caught(context.Background(), 1)
+
+	if len(rec.events) != 1 || rec.events[0] != "panic" {
+		t.Errorf("expected the default logger to capture the panic event, got %v", rec.events)
+	}
+}
@@ -0,0 +1,40 @@
+package scope
+
+// config holds the tunables shared by Catch, With, and Wrap. It is built
+// from Option values at call time, never exported directly.
+type config struct {
+	maxStackDepth    int
+	stopOnCloseError bool
+}
+
+// Option configures the behavior of Catch, With, or Wrap.
+type Option func(*config)
+
+// WithStackDepth bounds how many stack frames are captured when a panic is
+// recovered, overriding the package-wide default set by SetMaxStackDepth.
+func WithStackDepth(n int) Option {
+	return func(c *config) {
+		if n > 0 {
+			c.maxStackDepth = n
+		}
+	}
+}
+
+// WithStopOnCloseError makes With stop running registered closers as soon
+// as one of them fails or panics, instead of the default behavior of
+// running every closer regardless of earlier failures (matching how Go's
+// own defer chain never skips a deferred call because an earlier one
+// errored).
+func WithStopOnCloseError() Option {
+	return func(c *config) {
+		c.stopOnCloseError = true
+	}
+}
+
+func newConfig(opts ...Option) config {
+	c := config{}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
@@ -0,0 +1,188 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// retryableError marks an error as safe to retry. It is the type produced
+// by Retryable and unwrapped by the default RetryPolicy.IsRetryable check.
+type retryableError struct {
+	cause error
+}
+
+func (e *retryableError) Error() string { return e.cause.Error() }
+func (e *retryableError) Unwrap() error { return e.cause }
+
+// Retryable marks err as retryable so the default RetryPolicy will
+// re-invoke the wrapped function after seeing it. errors.Is and errors.As
+// still see through to the original cause, so Retryable can be applied at
+// the point an error is returned without disturbing existing error chains.
+// Retryable(nil) returns nil.
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &retryableError{cause: err}
+}
+
+func isMarkedRetryable(err error) bool {
+	var re *retryableError
+	return errors.As(err, &re)
+}
+
+// RetryPolicy configures Retry's attempt count, backoff schedule, and the
+// hooks used to classify errors and observe retries.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Factor         float64
+
+	// Jitter transforms a computed backoff into the delay actually slept.
+	// The default is full jitter: a uniform random duration in [0, d).
+	Jitter func(d time.Duration) time.Duration
+
+	// IsRetryable decides whether fn should be re-invoked after err. The
+	// default retries only errors marked via Retryable, and never retries
+	// context.DeadlineExceeded even if it was marked.
+	IsRetryable func(err error) bool
+
+	// OnRetry, if set, is called before sleeping ahead of each retry.
+	OnRetry func(attempt int, err error, nextDelay time.Duration)
+}
+
+func fullJitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(d)))
+}
+
+func defaultIsRetryable(err error) bool {
+	return isMarkedRetryable(err) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// defaultRetryPolicy returns the policy Retry starts from before applying
+// RetryOptions.
+func defaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Factor:         2.0,
+		Jitter:         fullJitter,
+		IsRetryable:    defaultIsRetryable,
+	}
+}
+
+// RetryOption configures a RetryPolicy built by Retry.
+type RetryOption func(*RetryPolicy)
+
+// WithMaxAttempts sets the total number of attempts, including the first.
+func WithMaxAttempts(n int) RetryOption {
+	return func(p *RetryPolicy) {
+		if n > 0 {
+			p.MaxAttempts = n
+		}
+	}
+}
+
+// WithInitialBackoff sets the delay before the first retry.
+func WithInitialBackoff(d time.Duration) RetryOption {
+	return func(p *RetryPolicy) { p.InitialBackoff = d }
+}
+
+// WithMaxBackoff caps the delay between retries.
+func WithMaxBackoff(d time.Duration) RetryOption {
+	return func(p *RetryPolicy) { p.MaxBackoff = d }
+}
+
+// WithBackoffFactor sets the exponential growth factor applied to the
+// backoff after each retry.
+func WithBackoffFactor(f float64) RetryOption {
+	return func(p *RetryPolicy) { p.Factor = f }
+}
+
+// WithJitter overrides how a computed backoff is turned into an actual
+// sleep duration. Pass a no-op (func(d time.Duration) time.Duration { return
+// d }) to disable jitter entirely.
+func WithJitter(jitter func(time.Duration) time.Duration) RetryOption {
+	return func(p *RetryPolicy) { p.Jitter = jitter }
+}
+
+// WithRetryable overrides which errors are retried.
+func WithRetryable(isRetryable func(error) bool) RetryOption {
+	return func(p *RetryPolicy) { p.IsRetryable = isRetryable }
+}
+
+// WithOnRetry registers a hook called before sleeping ahead of each retry.
+func WithOnRetry(onRetry func(attempt int, err error, nextDelay time.Duration)) RetryOption {
+	return func(p *RetryPolicy) { p.OnRetry = onRetry }
+}
+
+// RetryError is returned by Retry when every attempt has been exhausted, an
+// error was classified as non-retryable, or the context was cancelled
+// between attempts. It unwraps to the error from the last attempt, so
+// errors.Is and errors.As still match the underlying cause; after a
+// cancellation it unwraps to ctx.Err() instead.
+type RetryError struct {
+	attempts int
+	cause    error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("retry: giving up after %d attempt(s): %s", e.attempts, e.cause.Error())
+}
+
+func (e *RetryError) Unwrap() error { return e.cause }
+
+// Attempts reports how many times fn was invoked before Retry gave up.
+func (e *RetryError) Attempts() int { return e.attempts }
+
+// Retry wraps fn so that it is re-invoked under policy until it succeeds,
+// an error is classified as non-retryable, the attempt budget is
+// exhausted, or ctx is cancelled between attempts. Panics inside fn are
+// recovered the same way Catch recovers them and only retried if the
+// recovered value was itself wrapped with Retryable.
+func Retry[I, O any](fn func(context.Context, I) (O, error), opts ...RetryOption) func(context.Context, I) (O, error) {
+	policy := defaultRetryPolicy()
+	for _, opt := range opts {
+		opt(&policy)
+	}
+	caught := Catch(fn)
+
+	return func(ctx context.Context, input I) (O, error) {
+		backoff := policy.InitialBackoff
+
+		for attempt := 1; ; attempt++ {
+			output, err := caught(ctx, input)
+			if err == nil {
+				return output, nil
+			}
+
+			if attempt >= policy.MaxAttempts || !policy.IsRetryable(err) {
+				return output, &RetryError{attempts: attempt, cause: err}
+			}
+
+			delay := policy.Jitter(backoff)
+			if policy.OnRetry != nil {
+				policy.OnRetry(attempt, err, delay)
+			}
+
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return output, &RetryError{attempts: attempt, cause: ctx.Err()}
+			case <-timer.C:
+			}
+
+			backoff = time.Duration(math.Min(float64(policy.MaxBackoff), float64(backoff)*policy.Factor))
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func noJitter(d time.Duration) time.Duration { return d }
+
+func TestRetry(t *testing.T) {
+	t.Run("SucceedsWithoutRetry", func(t *testing.T) {
+		calls := 0
+		fn := func(ctx context.Context, i int) (int, error) {
+			calls++
+			return i, nil
+		}
+		r := Retry(fn, WithInitialBackoff(time.Millisecond), WithJitter(noJitter))
+		out, err := r(context.Background(), 5)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if out != 5 || calls != 1 {
+			t.Errorf("expected one call returning 5, got %d calls, out %d", calls, out)
+		}
+	})
+
+	t.Run("NonRetryableStopsImmediately", func(t *testing.T) {
+		calls := 0
+		cause := errors.New("not retryable")
+		fn := func(ctx context.Context, i int) (int, error) {
+			calls++
+			return 0, cause
+		}
+		r := Retry(fn, WithInitialBackoff(time.Millisecond), WithJitter(noJitter))
+		_, err := r(context.Background(), 0)
+		if calls != 1 {
+			t.Errorf("expected exactly one call for a non-retryable error, got %d", calls)
+		}
+		var re *RetryError
+		if !errors.As(err, &re) {
+			t.Fatalf("expected a *RetryError, got %v", err)
+		}
+		if re.Attempts() != 1 {
+			t.Errorf("expected Attempts() == 1, got %d", re.Attempts())
+		}
+		if !errors.Is(err, cause) {
+			t.Errorf("expected errors.Is to find the cause, got %v", err)
+		}
+	})
+
+	t.Run("RetriesMarkedErrorsUntilSuccess", func(t *testing.T) {
+		calls := 0
+		fn := func(ctx context.Context, i int) (int, error) {
+			calls++
+			if calls < 3 {
+				return 0, Retryable(errors.New("transient"))
+			}
+			return i * 2, nil
+		}
+		r := Retry(fn, WithMaxAttempts(5), WithInitialBackoff(time.Millisecond), WithJitter(noJitter))
+		out, err := r(context.Background(), 4)
+		if err != nil {
+			t.Fatalf("expected eventual success, got %v", err)
+		}
+		if calls != 3 || out != 8 {
+			t.Errorf("expected 3 calls and out 8, got %d calls, out %d", calls, out)
+		}
+	})
+
+	t.Run("ExhaustsAttemptsAndReportsCount", func(t *testing.T) {
+		calls := 0
+		cause := errors.New("always fails")
+		fn := func(ctx context.Context, i int) (int, error) {
+			calls++
+			return 0, Retryable(cause)
+		}
+		r := Retry(fn, WithMaxAttempts(3), WithInitialBackoff(time.Millisecond), WithJitter(noJitter))
+		_, err := r(context.Background(), 0)
+		var re *RetryError
+		if !errors.As(err, &re) {
+			t.Fatalf("expected a *RetryError, got %v", err)
+		}
+		if re.Attempts() != 3 || calls != 3 {
+			t.Errorf("expected 3 attempts, got Attempts()=%d calls=%d", re.Attempts(), calls)
+		}
+		if !errors.Is(err, cause) {
+			t.Errorf("expected errors.Is to find the cause, got %v", err)
+		}
+	})
+
+	t.Run("StopsWhenContextCancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+		fn := func(ctx context.Context, i int) (int, error) {
+			calls++
+			if calls == 1 {
+				cancel()
+			}
+			return 0, Retryable(errors.New("transient"))
+		}
+		r := Retry(fn, WithMaxAttempts(10), WithInitialBackoff(time.Millisecond), WithJitter(noJitter))
+		_, err := r(ctx, 0)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected errors.Is to find context.Canceled, got %v", err)
+		}
+		if calls != 1 {
+			t.Errorf("expected retry loop to stop after cancellation, got %d calls", calls)
+		}
+	})
+
+	t.Run("PanicMarkedRetryableRetries", func(t *testing.T) {
+		calls := 0
+		fn := func(ctx context.Context, i int) (int, error) {
+			calls++
+			if calls < 3 {
+				panic(Retryable(errors.New("transient panic")))
+			}
+			return i * 2, nil
+		}
+		r := Retry(fn, WithMaxAttempts(5), WithInitialBackoff(time.Millisecond), WithJitter(noJitter))
+		out, err := r(context.Background(), 4)
+		if err != nil {
+			t.Fatalf("expected eventual success, got %v", err)
+		}
+		if calls != 3 || out != 8 {
+			t.Errorf("expected 3 calls and out 8, got %d calls, out %d", calls, out)
+		}
+	})
+
+	t.Run("BarePanicDoesNotRetry", func(t *testing.T) {
+		calls := 0
+		fn := func(ctx context.Context, i int) (int, error) {
+			calls++
+			panic("not wrapped in Retryable")
+		}
+		r := Retry(fn, WithInitialBackoff(time.Millisecond), WithJitter(noJitter))
+		_, err := r(context.Background(), 0)
+		if calls != 1 {
+			t.Errorf("expected exactly one call for an unmarked panic, got %d", calls)
+		}
+		var re *RetryError
+		if !errors.As(err, &re) {
+			t.Fatalf("expected a *RetryError, got %v", err)
+		}
+		if re.Attempts() != 1 {
+			t.Errorf("expected Attempts() == 1, got %d", re.Attempts())
+		}
+		if !strings.Contains(err.Error(), "not wrapped in Retryable") {
+			t.Errorf("expected the panic value to surface in the error, got %v", err)
+		}
+	})
+
+	t.Run("DeadlineExceededNeverRetriedByDefault", func(t *testing.T) {
+		calls := 0
+		fn := func(ctx context.Context, i int) (int, error) {
+			calls++
+			return 0, Retryable(context.DeadlineExceeded)
+		}
+		r := Retry(fn, WithMaxAttempts(5), WithInitialBackoff(time.Millisecond), WithJitter(noJitter))
+		_, err := r(context.Background(), 0)
+		if calls != 1 {
+			t.Errorf("expected context.DeadlineExceeded to never retry by default, got %d calls", calls)
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected errors.Is to find context.DeadlineExceeded, got %v", err)
+		}
+	})
+}
@@ -5,19 +5,27 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"runtime"
 )
 
 type Void struct{}
 
-func Catch[I, O any](fn func(context.Context, I) (O, error)) func(context.Context, I) (O, error) {
+// recoverSkip accounts for the frames between a panic and the deferred
+// recover closure that calls newStackError: newStackError, the recover
+// closure, and runtime.gopanic (newStackError itself skips the
+// runtime.Callers frame). Counting from here puts frame zero of the trace
+// at the panicking call site instead of inside scope's own recovery
+// machinery.
+const recoverSkip = 3
+
+func Catch[I, O any](fn func(context.Context, I) (O, error), opts ...Option) func(context.Context, I) (O, error) {
+	cfg := newConfig(opts...)
 	return func(ctx context.Context, input I) (output O, err error) {
 		defer func() {
 			if r := recover(); r != nil {
-				if e, ok := r.(error); ok {
-					err = fmt.Errorf("panic caught: %w", e)
-				} else {
-					err = fmt.Errorf("panic caught: %+v", r)
-				}
+				se := newStackError(r, recoverSkip, cfg.maxStackDepth)
+				err = fmt.Errorf("panic caught: %w", se)
+				logPanic(ctx, input, se)
 			}
 		}()
 
@@ -25,36 +33,132 @@ func Catch[I, O any](fn func(context.Context, I) (O, error)) func(context.Contex
 	}
 }
 
-func With[I, O any](fn func(ctx context.Context, capture func(io.Closer), input I) (O, error)) func(context.Context, I) (O, error) {
+// closeRegistration is a cleanup closer registered via capture, captureFunc,
+// or captureCtx, remembered alongside the source location of the call that
+// registered it so a failing close can be traced back to its origin.
+type closeRegistration struct {
+	site  string
+	close func(context.Context) error
+}
+
+// With runs fn, collecting cleanup through capture, captureFunc, and
+// captureCtx. None of them close anything immediately: every registration
+// only runs once fn has returned or panicked, in reverse registration order,
+// mirroring how a chain of defer statements unwinds. Each close failure is
+// wrapped with the file:line of the call that registered it and joined into
+// the returned error; a panic inside a closer is recovered and joined the
+// same way rather than aborting the remaining cleanup. By default every
+// registered closer runs regardless of earlier failures; pass
+// WithStopOnCloseError to stop at the first one.
+func With[I, O any](
+	fn func(ctx context.Context, capture func(io.Closer), captureFunc func(func() error), captureCtx func(func(context.Context) error), input I) (O, error),
+	opts ...Option,
+) func(context.Context, I) (O, error) {
+	cfg := newConfig(opts...)
 	return func(ctx context.Context, input I) (output O, err error) {
 		errs := make([]error, 0, 4)
+		var closers []closeRegistration
 
-		defer func() {
-			if r := recover(); r != nil {
-				if e, ok := r.(error); ok {
-					e := fmt.Errorf("panic caught: %w", e)
-					errs = append(errs, e)
-				} else {
-					e := fmt.Errorf("panic caught: %+v", r)
-					errs = append(errs, e)
+		register := func(close func(context.Context) error) {
+			_, file, line, _ := runtime.Caller(2)
+			closers = append(closers, closeRegistration{
+				site:  fmt.Sprintf("%s:%d", file, line),
+				close: close,
+			})
+		}
+
+		capture := func(closer io.Closer) {
+			register(func(context.Context) error { return closer.Close() })
+		}
+		captureFunc := func(close func() error) {
+			register(func(context.Context) error { return close() })
+		}
+		captureCtx := func(close func(context.Context) error) {
+			register(close)
+		}
+
+		runClosers := func() {
+			closeFailures := 0
+			for i := len(closers) - 1; i >= 0; i-- {
+				c := closers[i]
+				func() {
+					defer func() {
+						if r := recover(); r != nil {
+							se := newStackError(r, recoverSkip, cfg.maxStackDepth)
+							closeErr := fmt.Errorf("panic closing %s: %w", c.site, se)
+							errs = append(errs, closeErr)
+							closeFailures++
+							logCloseError(ctx, input, c.site, closeErr)
+						}
+					}()
+					if closeErr := c.close(ctx); closeErr != nil {
+						wrapped := fmt.Errorf("close error at %s: %w", c.site, closeErr)
+						errs = append(errs, wrapped)
+						closeFailures++
+						logCloseError(ctx, input, c.site, wrapped)
+					}
+				}()
+				if cfg.stopOnCloseError && closeFailures > 0 {
+					return
 				}
+			}
+		}
 
+		defer func() {
+			if r := recover(); r != nil {
+				se := newStackError(r, recoverSkip, cfg.maxStackDepth)
+				errs = append(errs, fmt.Errorf("panic caught: %w", se))
+				logPanic(ctx, input, se)
 				output = *new(O) // zero value
-				err = errors.Join(errs...)
 			}
+
+			runClosers()
+			err = errors.Join(errs...)
 		}()
 
-		capture := func(closer io.Closer) {
-			if err := closer.Close(); err != nil {
-				errs = append(errs, err)
-			}
+		output, err = fn(ctx, capture, captureFunc, captureCtx, input)
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		return output, err
+	}
+}
+
+// Wrap runs fn with a check hook that only reports whether an error is
+// non-nil; check neither records the error nor calls catcher. When fn
+// returns a non-nil error, Wrap hands it to catcher before returning it.
+// catcher is also invoked, with a StackError cause, when fn panics. Unlike
+// Catch and With, Wrap never returns a bare error from the panic path
+// without first letting the caller's catcher observe it.
+func Wrap[S, I, O any](
+	fn func(ctx context.Context, check func(error) bool, input I, state S) (O, error),
+	catcher func(state S, err error),
+	opts ...Option,
+) func(context.Context, I, S) (O, error) {
+	cfg := newConfig(opts...)
+	return func(ctx context.Context, input I, state S) (output O, err error) {
+		check := func(e error) bool {
+			return e != nil
 		}
 
-		output, err = fn(ctx, capture, input)
+		defer func() {
+			if r := recover(); r != nil {
+				se := newStackError(r, recoverSkip, cfg.maxStackDepth)
+				panicErr := fmt.Errorf("panic caught: %w", se)
+				catcher(state, panicErr)
+				logPanic(ctx, input, se)
+				output = *new(O)
+				err = panicErr
+			}
+		}()
+
+		output, err = fn(ctx, check, input, state)
 		if err != nil {
-			errs = append(errs, err)
+			catcher(state, err)
+			logRollback(ctx, input, err)
 		}
 
-		return output, errors.Join(errs...)
+		return output, err
 	}
 }
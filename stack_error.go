@@ -0,0 +1,122 @@
+package scope
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// defaultMaxStackDepth bounds how many frames StackError collects when no
+// per-call option overrides it. It is stored as an int64 so SetMaxStackDepth
+// can update it without a mutex.
+var defaultMaxStackDepth int64 = 32
+
+// SetMaxStackDepth changes the package-wide default number of frames
+// captured by StackError. It affects every subsequent Catch, With, or Wrap
+// call that does not pass its own WithStackDepth option. n must be positive;
+// non-positive values are ignored.
+func SetMaxStackDepth(n int) {
+	if n <= 0 {
+		return
+	}
+	atomic.StoreInt64(&defaultMaxStackDepth, int64(n))
+}
+
+func maxStackDepth() int {
+	return int(atomic.LoadInt64(&defaultMaxStackDepth))
+}
+
+// StackError wraps a recovered panic value together with the stack frames
+// captured at the moment of recovery. It implements Unwrap so errors.Is and
+// errors.As keep working through the usual %w chain, and Format so callers
+// can opt into the full trace with "%+v" the same way pkg/errors-style
+// errors do.
+type StackError struct {
+	cause  error
+	frames []runtime.Frame
+}
+
+// newStackError builds a StackError from a recovered value, capturing up to
+// depth frames starting skip callers above newStackError itself. skip should
+// account for the deferred recover closure so the trace starts at the
+// panicking call site rather than inside scope's own recovery machinery.
+func newStackError(recovered any, skip int, depth int) *StackError {
+	var cause error
+	switch r := recovered.(type) {
+	case error:
+		cause = r
+	default:
+		cause = fmt.Errorf("%+v", r)
+	}
+
+	if depth <= 0 {
+		depth = maxStackDepth()
+	}
+
+	pcs := make([]uintptr, depth)
+	n := runtime.Callers(skip+1, pcs)
+	frames := make([]runtime.Frame, 0, n)
+	framesIter := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := framesIter.Next()
+		frames = append(frames, frame)
+		if !more {
+			break
+		}
+	}
+
+	return &StackError{cause: cause, frames: frames}
+}
+
+// Error returns the underlying cause's message, without the stack trace.
+func (e *StackError) Error() string {
+	return e.cause.Error()
+}
+
+// Unwrap exposes the recovered cause so errors.Is and errors.As can see
+// through the StackError wrapper.
+func (e *StackError) Unwrap() error {
+	return e.cause
+}
+
+// Frames returns the stack frames captured at recovery time, innermost
+// first: frame zero is the panicking call site.
+func (e *StackError) Frames() []runtime.Frame {
+	return e.frames
+}
+
+// StackTrace renders the captured frames as a multi-line "func\n\tfile:line"
+// trace, one frame per pair of lines.
+func (e *StackError) StackTrace() string {
+	var b strings.Builder
+	for _, frame := range e.frames {
+		b.WriteString(frame.Function)
+		b.WriteString("\n\t")
+		b.WriteString(frame.File)
+		b.WriteString(":")
+		b.WriteString(strconv.Itoa(frame.Line))
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Format implements fmt.Formatter. "%+v" prints the cause followed by its
+// full stack trace; every other verb falls back to the plain error message,
+// matching the convention popularized by pkg/errors.
+func (e *StackError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			io.WriteString(s, e.Error())
+			io.WriteString(s, "\n")
+			io.WriteString(s, e.StackTrace())
+			return
+		}
+		fallthrough
+	default:
+		io.WriteString(s, e.Error())
+	}
+}
@@ -0,0 +1,63 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func panickingSite() {
+	panic(errors.New("boom"))
+}
+
+func TestCatchStackError(t *testing.T) {
+	fn := func(ctx context.Context, i int) (int, error) {
+		panickingSite()
+		return i, nil
+	}
+	caught := Catch(fn)
+	_, err := caught(context.Background(), 1)
+
+	var se *StackError
+	if !errors.As(err, &se) {
+		t.Fatalf("expected error chain to contain a *StackError, got %v", err)
+	}
+
+	if len(se.Frames()) == 0 {
+		t.Fatal("expected at least one captured frame")
+	}
+	if !strings.Contains(se.Frames()[0].Function, "panickingSite") {
+		t.Errorf("expected top frame to be the panicking site, got %q", se.Frames()[0].Function)
+	}
+	for _, frame := range se.Frames() {
+		if strings.Contains(frame.Function, "newStackError") {
+			t.Errorf("recover machinery leaked into captured frames: %q", frame.Function)
+		}
+	}
+
+	if !strings.Contains(fmt.Sprintf("%+v", se), "boom") || !strings.Contains(fmt.Sprintf("%+v", se), "panickingSite") {
+		t.Errorf("expected %%+v to include cause and stack trace, got %q", fmt.Sprintf("%+v", se))
+	}
+	if got := fmt.Sprintf("%v", se); got != "boom" {
+		t.Errorf("expected %%v to be the short form %q, got %q", "boom", got)
+	}
+}
+
+func TestWithStackDepthOption(t *testing.T) {
+	fn := func(ctx context.Context, i int) (int, error) {
+		panickingSite()
+		return i, nil
+	}
+	caught := Catch(fn, WithStackDepth(1))
+	_, err := caught(context.Background(), 1)
+
+	var se *StackError
+	if !errors.As(err, &se) {
+		t.Fatalf("expected error chain to contain a *StackError, got %v", err)
+	}
+	if len(se.Frames()) > 1 {
+		t.Errorf("expected WithStackDepth(1) to cap frames at 1, got %d", len(se.Frames()))
+	}
+}
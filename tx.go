@@ -0,0 +1,90 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Tx turns the Wrap pattern into transaction-shaped ergonomics: begin opens
+// a session of type S, commit finalizes it on success, and rollback undoes
+// it on error or panic. Unlike Wrap's catcher, rollback's own error is
+// always joined into the result rather than swallowed.
+//
+// Inside body, savepoint("name") pushes a nestable checkpoint. Calling
+// check(err) with a non-nil err pops the nearest checkpoint and calls
+// rollback with that checkpoint's name, joining any rollback error, and
+// reports true so body can recover and keep doing outer work instead of
+// aborting the whole transaction. The err passed to check is also joined
+// into Tx's returned error, even when commit then succeeds. body's own returned error (or a panic)
+// triggers one final rollback with an empty savepoint name, meaning "undo
+// everything still open". rollback implementations backed by a real
+// database should issue ROLLBACK TO SAVEPOINT <name> when given a
+// non-empty name, and a full ROLLBACK otherwise.
+func Tx[S, I, O any](
+	begin func(ctx context.Context, input I) (S, error),
+	commit func(ctx context.Context, state S) error,
+	rollback func(ctx context.Context, state S, savepoint string, cause error) error,
+	body func(ctx context.Context, savepoint func(name string), check func(error) bool, state S, input I) (O, error),
+	opts ...Option,
+) func(context.Context, I) (O, error) {
+	cfg := newConfig(opts...)
+
+	return func(ctx context.Context, input I) (output O, err error) {
+		state, beginErr := begin(ctx, input)
+		if beginErr != nil {
+			return *new(O), fmt.Errorf("tx: begin: %w", beginErr)
+		}
+
+		errs := make([]error, 0, 4)
+		var savepoints []string
+
+		rollbackTo := func(savepointName string, cause error) {
+			if rbErr := rollback(ctx, state, savepointName, cause); rbErr != nil {
+				if savepointName != "" {
+					errs = append(errs, fmt.Errorf("tx: rollback to savepoint %q: %w", savepointName, rbErr))
+				} else {
+					errs = append(errs, fmt.Errorf("tx: rollback: %w", rbErr))
+				}
+			}
+			errs = append(errs, cause)
+		}
+
+		savepoint := func(name string) {
+			savepoints = append(savepoints, name)
+		}
+
+		check := func(e error) bool {
+			if e == nil {
+				return false
+			}
+			var name string
+			if n := len(savepoints); n > 0 {
+				name = savepoints[n-1]
+				savepoints = savepoints[:n-1]
+			}
+			rollbackTo(name, e)
+			return true
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				se := newStackError(r, recoverSkip, cfg.maxStackDepth)
+				rollbackTo("", fmt.Errorf("panic caught: %w", se))
+				output = *new(O)
+				err = errors.Join(errs...)
+			}
+		}()
+
+		output, err = body(ctx, savepoint, check, state, input)
+		if err != nil {
+			rollbackTo("", err)
+			return output, errors.Join(errs...)
+		}
+
+		if commitErr := commit(ctx, state); commitErr != nil {
+			errs = append(errs, fmt.Errorf("tx: commit: %w", commitErr))
+		}
+		return output, errors.Join(errs...)
+	}
+}
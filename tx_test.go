@@ -0,0 +1,199 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type fakeSession struct {
+	committed  bool
+	rollbacks  []string
+	savepoints []string
+}
+
+func TestTx(t *testing.T) {
+	t.Run("CommitsOnSuccess", func(t *testing.T) {
+		session := &fakeSession{}
+		begin := func(ctx context.Context, input string) (*fakeSession, error) {
+			return session, nil
+		}
+		commit := func(ctx context.Context, s *fakeSession) error {
+			s.committed = true
+			return nil
+		}
+		rollback := func(ctx context.Context, s *fakeSession, savepoint string, cause error) error {
+			s.rollbacks = append(s.rollbacks, cause.Error())
+			s.savepoints = append(s.savepoints, savepoint)
+			return nil
+		}
+		body := func(ctx context.Context, savepoint func(string), check func(error) bool, s *fakeSession, input string) (int, error) {
+			return len(input), nil
+		}
+
+		tx := Tx(begin, commit, rollback, body)
+		out, err := tx(context.Background(), "hello")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if out != 5 {
+			t.Errorf("expected 5, got %d", out)
+		}
+		if !session.committed {
+			t.Error("expected commit to run on success")
+		}
+		if len(session.rollbacks) != 0 {
+			t.Errorf("expected no rollback on success, got %v", session.rollbacks)
+		}
+	})
+
+	t.Run("RollsBackOnError", func(t *testing.T) {
+		session := &fakeSession{}
+		bodyErr := errors.New("body failed")
+		begin := func(ctx context.Context, input string) (*fakeSession, error) {
+			return session, nil
+		}
+		commit := func(ctx context.Context, s *fakeSession) error { return nil }
+		rollback := func(ctx context.Context, s *fakeSession, savepoint string, cause error) error {
+			s.rollbacks = append(s.rollbacks, cause.Error())
+			s.savepoints = append(s.savepoints, savepoint)
+			return nil
+		}
+		body := func(ctx context.Context, savepoint func(string), check func(error) bool, s *fakeSession, input string) (int, error) {
+			return 0, bodyErr
+		}
+
+		tx := Tx(begin, commit, rollback, body)
+		_, err := tx(context.Background(), "x")
+		if !errors.Is(err, bodyErr) {
+			t.Fatalf("expected error to include bodyErr, got %v", err)
+		}
+		if session.committed {
+			t.Error("expected commit not to run on error")
+		}
+		if len(session.rollbacks) != 1 {
+			t.Errorf("expected exactly one rollback, got %v", session.rollbacks)
+		}
+		if session.savepoints[0] != "" {
+			t.Errorf("expected a whole-transaction rollback (empty savepoint), got %q", session.savepoints[0])
+		}
+	})
+
+	t.Run("CommitErrorIsJoinedNotSwallowed", func(t *testing.T) {
+		session := &fakeSession{}
+		commitErr := errors.New("commit failed")
+		begin := func(ctx context.Context, input string) (*fakeSession, error) { return session, nil }
+		commit := func(ctx context.Context, s *fakeSession) error { return commitErr }
+		rollback := func(ctx context.Context, s *fakeSession, savepoint string, cause error) error { return nil }
+		body := func(ctx context.Context, savepoint func(string), check func(error) bool, s *fakeSession, input string) (int, error) {
+			return 1, nil
+		}
+
+		tx := Tx(begin, commit, rollback, body)
+		_, err := tx(context.Background(), "x")
+		if !errors.Is(err, commitErr) {
+			t.Fatalf("expected commit error to surface, got %v", err)
+		}
+	})
+
+	t.Run("SavepointRollsBackNestedFailureWithoutAbortingOuterWork", func(t *testing.T) {
+		session := &fakeSession{}
+		innerErr := errors.New("inner step failed")
+		begin := func(ctx context.Context, input string) (*fakeSession, error) { return session, nil }
+		commit := func(ctx context.Context, s *fakeSession) error {
+			s.committed = true
+			return nil
+		}
+		rollback := func(ctx context.Context, s *fakeSession, savepoint string, cause error) error {
+			s.rollbacks = append(s.rollbacks, cause.Error())
+			s.savepoints = append(s.savepoints, savepoint)
+			return nil
+		}
+		body := func(ctx context.Context, savepoint func(string), check func(error) bool, s *fakeSession, input string) (int, error) {
+			savepoint("inner-step")
+			if check(innerErr) {
+				// Nested failure recovered; outer work continues.
+			}
+			return 42, nil
+		}
+
+		tx := Tx(begin, commit, rollback, body)
+		out, err := tx(context.Background(), "x")
+		if err == nil || !errors.Is(err, innerErr) {
+			t.Fatalf("expected the joined error to include the recovered savepoint error, got %v", err)
+		}
+		if out != 42 {
+			t.Errorf("expected outer work to still produce 42, got %d", out)
+		}
+		if !session.committed {
+			t.Error("expected commit to run since the outer body returned no error")
+		}
+		if len(session.rollbacks) != 1 || session.rollbacks[0] != innerErr.Error() {
+			t.Errorf("expected rollback to be called with the savepoint's cause, got %v", session.rollbacks)
+		}
+		if len(session.savepoints) != 1 || session.savepoints[0] != "inner-step" {
+			t.Errorf("expected rollback to receive the nearest savepoint name, got %v", session.savepoints)
+		}
+	})
+
+	t.Run("PanicTriggersRollback", func(t *testing.T) {
+		session := &fakeSession{}
+		begin := func(ctx context.Context, input string) (*fakeSession, error) { return session, nil }
+		commit := func(ctx context.Context, s *fakeSession) error {
+			s.committed = true
+			return nil
+		}
+		rollback := func(ctx context.Context, s *fakeSession, savepoint string, cause error) error {
+			s.rollbacks = append(s.rollbacks, cause.Error())
+			s.savepoints = append(s.savepoints, savepoint)
+			return nil
+		}
+		body := func(ctx context.Context, savepoint func(string), check func(error) bool, s *fakeSession, input string) (int, error) {
+			panic("boom")
+		}
+
+		tx := Tx(begin, commit, rollback, body)
+		out, err := tx(context.Background(), "x")
+		if err == nil {
+			t.Fatal("expected error from panic, got nil")
+		}
+		if out != 0 {
+			t.Errorf("expected zero value on panic, got %d", out)
+		}
+		if session.committed {
+			t.Error("expected commit not to run after a panic")
+		}
+		if len(session.rollbacks) != 1 {
+			t.Errorf("expected exactly one rollback after a panic, got %v", session.rollbacks)
+		}
+		if session.savepoints[0] != "" {
+			t.Errorf("expected a whole-transaction rollback (empty savepoint) on panic, got %q", session.savepoints[0])
+		}
+	})
+
+	t.Run("BeginErrorSkipsCommitAndRollback", func(t *testing.T) {
+		beginErr := errors.New("begin failed")
+		rollbackCalled := false
+		begin := func(ctx context.Context, input string) (*fakeSession, error) {
+			return nil, beginErr
+		}
+		commit := func(ctx context.Context, s *fakeSession) error { return nil }
+		rollback := func(ctx context.Context, s *fakeSession, savepoint string, cause error) error {
+			rollbackCalled = true
+			return nil
+		}
+		body := func(ctx context.Context, savepoint func(string), check func(error) bool, s *fakeSession, input string) (int, error) {
+			t.Fatal("body should not run when begin fails")
+			return 0, nil
+		}
+
+		tx := Tx(begin, commit, rollback, body)
+		_, err := tx(context.Background(), "x")
+		if !errors.Is(err, beginErr) {
+			t.Fatalf("expected error to include beginErr, got %v", err)
+		}
+		if rollbackCalled {
+			t.Error("expected rollback not to run when begin itself failed")
+		}
+	})
+}
@@ -0,0 +1,186 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type mockCloser struct {
+	err error
+}
+
+func (m *mockCloser) Close() error {
+	return m.err
+}
+
+func TestWith(t *testing.T) {
+	t.Run("Normal", func(t *testing.T) {
+		fn := func(ctx context.Context, capture func(io.Closer), captureFunc func(func() error), captureCtx func(func(context.Context) error), i int) (int, error) {
+			closer := &mockCloser{err: nil}
+			capture(closer)
+			return i + 1, nil
+		}
+		w := With(fn)
+		out, err := w(context.Background(), 10)
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if out != 11 {
+			t.Errorf("expected 11, got %d", out)
+		}
+	})
+
+	t.Run("FnError", func(t *testing.T) {
+		fnErr := errors.New("fn error")
+		fn := func(ctx context.Context, capture func(io.Closer), captureFunc func(func() error), captureCtx func(func(context.Context) error), i int) (int, error) {
+			return 0, fnErr
+		}
+		w := With(fn)
+		_, err := w(context.Background(), 0)
+		if !errors.Is(err, fnErr) {
+			t.Errorf("expected error to include fnErr, got %v", err)
+		}
+	})
+
+	t.Run("CloserError", func(t *testing.T) {
+		closeErr := errors.New("close error")
+		fn := func(ctx context.Context, capture func(io.Closer), captureFunc func(func() error), captureCtx func(func(context.Context) error), i int) (int, error) {
+			capture(&mockCloser{err: closeErr})
+			return 100, nil
+		}
+		w := With(fn)
+		out, err := w(context.Background(), 0)
+		if !errors.Is(err, closeErr) {
+			t.Errorf("expected error to include closeErr, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "with_test.go") {
+			t.Errorf("expected error to carry the registration site, got %v", err)
+		}
+		if out != 100 {
+			t.Errorf("expected output 100 even if closer fails, got %d", out)
+		}
+	})
+
+	t.Run("MultipleErrors", func(t *testing.T) {
+		fnErr := errors.New("fn error")
+		closeErr := errors.New("close error")
+		fn := func(ctx context.Context, capture func(io.Closer), captureFunc func(func() error), captureCtx func(func(context.Context) error), i int) (int, error) {
+			capture(&mockCloser{err: closeErr})
+			return 0, fnErr
+		}
+		w := With(fn)
+		_, err := w(context.Background(), 0)
+		if !errors.Is(err, fnErr) || !errors.Is(err, closeErr) {
+			t.Errorf("expected error to join both, got %v", err)
+		}
+	})
+
+	t.Run("PanicHandling", func(t *testing.T) {
+		fn := func(ctx context.Context, capture func(io.Closer), captureFunc func(func() error), captureCtx func(func(context.Context) error), i int) (int, error) {
+			panic("oops")
+		}
+		w := With(fn)
+		out, err := w(context.Background(), 0)
+		if err == nil {
+			t.Fatal("expected error from panic, got nil")
+		}
+		if !strings.Contains(err.Error(), "panic caught: oops") {
+			t.Errorf("unexpected error message: %v", err)
+		}
+		if out != 0 {
+			t.Errorf("expected zero value output on panic, got %d", out)
+		}
+	})
+
+	t.Run("LIFOOrder", func(t *testing.T) {
+		var order []string
+		fn := func(ctx context.Context, capture func(io.Closer), captureFunc func(func() error), captureCtx func(func(context.Context) error), i int) (int, error) {
+			captureFunc(func() error { order = append(order, "first"); return nil })
+			captureCtx(func(context.Context) error { order = append(order, "second"); return nil })
+			capture(&closerFunc{close: func() error { order = append(order, "third"); return nil }})
+			return 0, nil
+		}
+		w := With(fn)
+		if _, err := w(context.Background(), 0); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		want := []string{"third", "second", "first"}
+		if len(order) != len(want) {
+			t.Fatalf("expected %v, got %v", want, order)
+		}
+		for i := range want {
+			if order[i] != want[i] {
+				t.Errorf("expected close order %v, got %v", want, order)
+				break
+			}
+		}
+	})
+
+	t.Run("StopOnFirstCloseError", func(t *testing.T) {
+		ran := 0
+		firstErr := errors.New("first close error")
+		fn := func(ctx context.Context, capture func(io.Closer), captureFunc func(func() error), captureCtx func(func(context.Context) error), i int) (int, error) {
+			captureFunc(func() error { ran++; return nil }) // registered first, closes last
+			captureFunc(func() error { ran++; return firstErr })
+			return 0, nil
+		}
+		w := With(fn, WithStopOnCloseError())
+		_, err := w(context.Background(), 0)
+		if !errors.Is(err, firstErr) {
+			t.Fatalf("expected error to include firstErr, got %v", err)
+		}
+		if ran != 1 {
+			t.Errorf("expected cleanup to stop after the first failure, ran %d closers", ran)
+		}
+	})
+
+	t.Run("StopOnFirstCloseErrorIgnoresPreexistingFnError", func(t *testing.T) {
+		ran := 0
+		fnErr := errors.New("fn error")
+		fn := func(ctx context.Context, capture func(io.Closer), captureFunc func(func() error), captureCtx func(func(context.Context) error), i int) (int, error) {
+			captureFunc(func() error { ran++; return nil })
+			captureFunc(func() error { ran++; return nil })
+			captureFunc(func() error { ran++; return nil })
+			return 0, fnErr
+		}
+		w := With(fn, WithStopOnCloseError())
+		_, err := w(context.Background(), 0)
+		if !errors.Is(err, fnErr) {
+			t.Fatalf("expected error to include fnErr, got %v", err)
+		}
+		if ran != 3 {
+			t.Errorf("expected all 3 closers to run since none of them failed, ran %d", ran)
+		}
+	})
+
+	t.Run("PanicInsideCloserDoesNotAbortCleanup", func(t *testing.T) {
+		secondRan := false
+		fn := func(ctx context.Context, capture func(io.Closer), captureFunc func(func() error), captureCtx func(func(context.Context) error), i int) (int, error) {
+			captureFunc(func() error { secondRan = true; return nil }) // registered first, closes last
+			captureFunc(func() error { panic("closer exploded") })
+			return 0, nil
+		}
+		w := With(fn)
+		_, err := w(context.Background(), 0)
+		if err == nil {
+			t.Fatal("expected error from panicking closer, got nil")
+		}
+		if !strings.Contains(err.Error(), "closer exploded") {
+			t.Errorf("expected joined error to mention the panic value, got %v", err)
+		}
+		if !secondRan {
+			t.Error("expected remaining closers to still run after one panicked")
+		}
+	})
+}
+
+type closerFunc struct {
+	close func() error
+}
+
+func (c *closerFunc) Close() error {
+	return c.close()
+}